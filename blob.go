@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseBlobArg splits a "blob" subcommand argument of the form
+// "path/to/file.go:42-50" (or "path/to/file.go:42", or a bare path with no
+// line range) into its path and optional start/end line numbers. A missing
+// line range is returned as 0, 0.
+func parseBlobArg(arg string) (path string, startLine, endLine int) {
+	parts := strings.SplitN(arg, ":", 2)
+	if len(parts) == 1 {
+		return parts[0], 0, 0
+	}
+
+	path = parts[0]
+	lines := strings.SplitN(parts[1], "-", 2)
+
+	startLine, err := strconv.Atoi(lines[0])
+	if err != nil {
+		return path, 0, 0
+	}
+
+	if len(lines) == 1 {
+		return path, startLine, 0
+	}
+
+	endLine, err = strconv.Atoi(lines[1])
+	if err != nil {
+		return path, startLine, 0
+	}
+
+	return path, startLine, endLine
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestParseBlobArg(t *testing.T) {
+	cases := []struct {
+		name          string
+		arg           string
+		wantPath      string
+		wantStartLine int
+		wantEndLine   int
+	}{
+		{"bare path", "main.go", "main.go", 0, 0},
+		{"single line", "main.go:42", "main.go", 42, 0},
+		{"line range", "main.go:42-50", "main.go", 42, 50},
+		{"nested path with range", "pkg/sub/file.go:10-20", "pkg/sub/file.go", 10, 20},
+		{"non-numeric line", "main.go:abc", "main.go", 0, 0},
+		{"non-numeric end line", "main.go:42-abc", "main.go", 42, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path, startLine, endLine := parseBlobArg(c.arg)
+			if path != c.wantPath || startLine != c.wantStartLine || endLine != c.wantEndLine {
+				t.Errorf("parseBlobArg(%q) = (%q, %d, %d), want (%q, %d, %d)",
+					c.arg, path, startLine, endLine, c.wantPath, c.wantStartLine, c.wantEndLine)
+			}
+		})
+	}
+}
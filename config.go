@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config is git-open's on-disk configuration, loaded from
+// ~/.config/git-open/config.yaml (or GIT_OPEN_CONFIG if set). Per-host
+// tokens and API base URLs here are overridden by the GITLAB_API_KEY /
+// GITLAB_HOST / GITHUB_API_KEY / GITHUB_TOKEN env vars when those are set.
+type Config struct {
+	Hosts map[string]HostConfig `yaml:"hosts"`
+}
+
+// HostConfig holds the settings for a single git hosting provider, keyed by
+// hostname in Config.Hosts.
+type HostConfig struct {
+	Token   string `yaml:"token"`
+	APIBase string `yaml:"api_base"`
+}
+
+func configPath() (string, error) {
+	if path := os.Getenv("GIT_OPEN_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".config", "git-open", "config.yaml"), nil
+}
+
+func loadConfig() (*Config, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func saveConfig(cfg *Config) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
+
+func (c *Config) tokenFor(host string) string {
+	return c.Hosts[host].Token
+}
+
+func (c *Config) apiBaseFor(host string) string {
+	return c.Hosts[host].APIBase
+}
+
+func (c *Config) setToken(host, token string) {
+	if c.Hosts == nil {
+		c.Hosts = map[string]HostConfig{}
+	}
+	hc := c.Hosts[host]
+	hc.Token = token
+	c.Hosts[host] = hc
+}
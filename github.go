@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+type githubHost struct {
+	apiBase string
+	host    string
+	org     string
+	name    string
+	token   string
+}
+
+func newGitHubHost(host, org, name string, cfg *Config) *githubHost {
+	apiBase := "https://api.github.com"
+	if host != "github.com" {
+		apiBase = "https://" + host + "/api/v3"
+	}
+	if override := cfg.apiBaseFor(host); override != "" {
+		apiBase = override
+	}
+
+	token := os.Getenv("GITHUB_API_KEY")
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	if token == "" {
+		token = cfg.tokenFor(host)
+	}
+
+	return &githubHost{apiBase: apiBase, host: host, org: org, name: name, token: token}
+}
+
+func (h *githubHost) ValidateToken() error {
+	var me struct {
+		Login string `json:"login"`
+	}
+	return h.get(h.apiBase+"/user", &me)
+}
+
+func (h *githubHost) HomeURL() string {
+	return fmt.Sprintf("https://%s/%s/%s", h.host, h.org, h.name)
+}
+
+func (h *githubHost) IssueURL(id int) (string, error) {
+	return fmt.Sprintf("%s/issues/%d", h.HomeURL(), id), nil
+}
+
+func (h *githubHost) CIStatusURL(sha string) (string, error) {
+	var result struct {
+		CheckRuns []struct {
+			HTMLURL string `json:"html_url"`
+		} `json:"check_runs"`
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s/check-runs", h.apiBase, h.org, h.name, sha)
+	if err := h.get(url, &result); err != nil {
+		return "", err
+	} else if len(result.CheckRuns) == 0 {
+		return fmt.Sprintf("%s/commit/%s/checks", h.HomeURL(), sha), nil
+	}
+
+	return result.CheckRuns[0].HTMLURL, nil
+}
+
+func (h *githubHost) CommitURL(sha string) string {
+	return fmt.Sprintf("%s/commit/%s", h.HomeURL(), sha)
+}
+
+func (h *githubHost) BlobURL(branch, path string, startLine, endLine int) string {
+	url := fmt.Sprintf("%s/blob/%s/%s", h.HomeURL(), escapeBlobPath(branch), escapeBlobPath(path))
+	switch {
+	case startLine <= 0:
+		return url
+	case endLine <= 0 || endLine == startLine:
+		return fmt.Sprintf("%s#L%d", url, startLine)
+	default:
+		return fmt.Sprintf("%s#L%d-L%d", url, startLine, endLine)
+	}
+}
+
+func (h *githubHost) MyOpenIssues() ([]Issue, error) {
+	var me struct {
+		Login string `json:"login"`
+	}
+	if err := h.get(h.apiBase+"/user", &me); err != nil {
+		return nil, err
+	}
+
+	var issues []struct {
+		Number      int              `json:"number"`
+		Title       string           `json:"title"`
+		HTMLURL     string           `json:"html_url"`
+		PullRequest *json.RawMessage `json:"pull_request,omitempty"`
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/issues?state=open&assignee=%s", h.apiBase, h.org, h.name, me.Login)
+	if err := h.get(url, &issues); err != nil {
+		return nil, err
+	}
+
+	result := make([]Issue, 0, len(issues))
+	for _, issue := range issues {
+		if issue.PullRequest != nil {
+			// GitHub's issues endpoint also returns pull requests.
+			continue
+		}
+		result = append(result, Issue{ID: issue.Number, Title: issue.Title, URL: issue.HTMLURL})
+	}
+
+	return result, nil
+}
+
+// PullRequestURL looks up the open PR whose head is headOrg:branch.
+// headOrg is the namespace the branch was actually pushed to, which differs
+// from h.org when the user is working against a fork (e.g. --remote
+// upstream with the branch pushed to their own "origin" fork).
+func (h *githubHost) PullRequestURL(headOrg, branch string) (string, error) {
+	log.Printf("finding PRs for %s/%s on branch %s:%s", h.org, h.name, headOrg, branch)
+
+	var pulls []struct {
+		HTMLURL string `json:"html_url"`
+	}
+
+	url := fmt.Sprintf(
+		"%s/repos/%s/%s/pulls?state=open&head=%s:%s",
+		h.apiBase, h.org, h.name, url.QueryEscape(headOrg), url.QueryEscape(branch),
+	)
+	if err := h.get(url, &pulls); err != nil {
+		return "", err
+	} else if len(pulls) == 0 {
+		return "", ErrNoPullRequest
+	}
+
+	return pulls[0].HTMLURL, nil
+}
+
+// NewPullRequestURL builds a compare URL from target to headOrg:branch. The
+// owner prefix is only needed (and only valid) when headOrg differs from
+// this repo's own org, i.e. when branch lives in a fork.
+func (h *githubHost) NewPullRequestURL(headOrg, branch, target string) string {
+	head := escapeBlobPath(branch)
+	if headOrg != "" && headOrg != h.org {
+		head = escapeBlobPath(headOrg) + ":" + escapeBlobPath(branch)
+	}
+	return fmt.Sprintf("%s/compare/%s...%s?expand=1", h.HomeURL(), escapeBlobPath(target), head)
+}
+
+func (h *githubHost) DefaultBranch() (string, error) {
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s", h.apiBase, h.org, h.name)
+	if err := h.get(url, &repo); err != nil {
+		return "", err
+	}
+
+	return repo.DefaultBranch, nil
+}
+
+func (h *githubHost) get(url string, out interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if h.token != "" {
+		req.Header.Set("Authorization", "token "+h.token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("github api returned %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
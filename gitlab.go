@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+type gitlabHost struct {
+	client *gitlab.Client
+	host   string
+	org    string
+	name   string
+}
+
+func newGitLabHost(host, org, name string, cfg *Config) *gitlabHost {
+	token := os.Getenv("GITLAB_API_KEY")
+	if token == "" {
+		token = cfg.tokenFor(host)
+	}
+	client := gitlab.NewClient(nil, token)
+
+	apiBase := os.Getenv("GITLAB_HOST")
+	if apiBase == "" {
+		apiBase = cfg.apiBaseFor(host)
+	}
+	if apiBase != "" {
+		client.SetBaseURL(apiBase)
+	} else if host != "gitlab.com" {
+		client.SetBaseURL("https://" + host)
+	}
+
+	return &gitlabHost{client: client, host: host, org: org, name: name}
+}
+
+func (h *gitlabHost) ValidateToken() error {
+	_, _, err := h.client.Users.CurrentUser()
+	return err
+}
+
+func (h *gitlabHost) HomeURL() string {
+	return fmt.Sprintf("https://%s/%s/%s", h.host, h.org, h.name)
+}
+
+func (h *gitlabHost) IssueURL(id int) (string, error) {
+	return fmt.Sprintf("%s/-/issues/%d", h.HomeURL(), id), nil
+}
+
+func (h *gitlabHost) CIStatusURL(sha string) (string, error) {
+	projID, err := h.projectID()
+	if err != nil {
+		return "", err
+	}
+
+	opt := &gitlab.ListProjectPipelinesOptions{SHA: stringptr(sha)}
+	pipelines, _, err := h.client.Pipelines.ListProjectPipelines(projID, opt)
+	if err != nil {
+		return "", err
+	} else if len(pipelines) == 0 {
+		return fmt.Sprintf("%s/-/pipelines", h.HomeURL()), nil
+	}
+
+	return pipelines[0].WebURL, nil
+}
+
+func (h *gitlabHost) CommitURL(sha string) string {
+	return fmt.Sprintf("%s/-/commit/%s", h.HomeURL(), sha)
+}
+
+func (h *gitlabHost) BlobURL(branch, path string, startLine, endLine int) string {
+	url := fmt.Sprintf("%s/-/blob/%s/%s", h.HomeURL(), escapeBlobPath(branch), escapeBlobPath(path))
+	switch {
+	case startLine <= 0:
+		return url
+	case endLine <= 0 || endLine == startLine:
+		return fmt.Sprintf("%s#L%d", url, startLine)
+	default:
+		return fmt.Sprintf("%s#L%d-%d", url, startLine, endLine)
+	}
+}
+
+func (h *gitlabHost) MyOpenIssues() ([]Issue, error) {
+	projID, err := h.projectID()
+	if err != nil {
+		return nil, err
+	}
+
+	opt := &gitlab.ListProjectIssuesOptions{
+		Scope: stringptr("assigned_to_me"),
+		State: stringptr("opened"),
+	}
+
+	issues, _, err := h.client.Issues.ListProjectIssues(projID, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Issue, len(issues))
+	for i, issue := range issues {
+		result[i] = Issue{ID: issue.IID, Title: issue.Title, URL: issue.WebURL}
+	}
+
+	return result, nil
+}
+
+// PullRequestURL looks up the open MR targeting this project whose source
+// branch is named branch. GitLab's source_branch filter matches on the
+// branch name alone, so this works whether branch lives in this project or
+// in a fork of it (headOrg is unused for that reason, but kept to satisfy
+// the Host interface used by the fork-aware GitHub implementation).
+func (h *gitlabHost) PullRequestURL(headOrg, branch string) (string, error) {
+	projID, err := h.projectID()
+	if err != nil {
+		return "", err
+	}
+	return getMRURL(h.client, projID, branch)
+}
+
+func (h *gitlabHost) DefaultBranch() (string, error) {
+	proj, err := h.getProject()
+	if err != nil {
+		return "", err
+	}
+	return proj.DefaultBranch, nil
+}
+
+// NewPullRequestURL points at this project's "new merge request" page. If
+// headOrg names a fork of this project, its source_project_id is resolved
+// and included so the compare view defaults to the fork's branch instead of
+// a same-project branch that doesn't exist.
+func (h *gitlabHost) NewPullRequestURL(headOrg, branch, target string) string {
+	url := fmt.Sprintf(
+		"%s/-/merge_requests/new?merge_request[source_branch]=%s&merge_request[target_branch]=%s",
+		h.HomeURL(), url.QueryEscape(branch), url.QueryEscape(target),
+	)
+
+	if headOrg != "" && headOrg != h.org {
+		if sourceProj, err := h.getProjectAt(headOrg); err == nil {
+			url += fmt.Sprintf("&merge_request[source_project_id]=%d", sourceProj.ID)
+		}
+	}
+
+	return url
+}
+
+func (h *gitlabHost) projectID() (string, error) {
+	proj, err := h.getProject()
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(proj.ID), nil
+}
+
+func (h *gitlabHost) getProject() (*gitlab.Project, error) {
+	return h.getProjectAt(h.org)
+}
+
+func (h *gitlabHost) getProjectAt(org string) (*gitlab.Project, error) {
+	path := org + "/" + h.name
+	log.Printf("getting gitlab project for %s", path)
+
+	proj, _, err := h.client.Projects.GetProject(path, nil)
+	return proj, err
+}
+
+func getMRURL(client *gitlab.Client, projID, branch string) (string, error) {
+	log.Printf("finding MRs for project id(%s) on branch %s", projID, branch)
+
+	opt := &gitlab.ListProjectMergeRequestsOptions{
+		SourceBranch: stringptr(branch),
+		State:        stringptr("opened"),
+		View:         stringptr("simple"),
+	}
+
+	mrs, _, err := client.MergeRequests.ListProjectMergeRequests(projID, opt)
+	if err != nil {
+		return "", err
+	} else if len(mrs) == 0 {
+		return "", ErrNoPullRequest
+	}
+
+	return mrs[0].WebURL, nil
+}
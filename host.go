@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrNoPullRequest is returned by PullRequestURL when no open pull/merge
+// request exists for the given branch, so callers can fall back to opening
+// a "create new pull request" URL instead.
+var ErrNoPullRequest = errors.New("no open pull request found for branch")
+
+// Host abstracts over the git hosting provider (GitHub, GitLab, ...) so that
+// the subcommands in main.go don't need to know which API they're talking
+// to. Each provider lives in its own file (github.go, gitlab.go) and is
+// selected in newHost based on the remote's hostname.
+type Host interface {
+	// PullRequestURL returns the web URL of the open pull/merge request
+	// whose head is headOrg/branch, or ErrNoPullRequest if none exists.
+	// headOrg is the namespace the branch actually lives in, which may
+	// differ from this Host's own org in a fork+upstream workflow.
+	PullRequestURL(headOrg, branch string) (string, error)
+	// NewPullRequestURL returns the URL to open to create a pull/merge
+	// request from headOrg/branch into target.
+	NewPullRequestURL(headOrg, branch, target string) string
+	HomeURL() string
+	IssueURL(id int) (string, error)
+	CIStatusURL(sha string) (string, error)
+	CommitURL(sha string) string
+	BlobURL(branch, path string, startLine, endLine int) string
+	MyOpenIssues() ([]Issue, error)
+	DefaultBranch() (string, error)
+	ValidateToken() error
+}
+
+// Issue is the subset of an issue's data git-open needs to list it and open
+// it in a browser.
+type Issue struct {
+	ID    int
+	Title string
+	URL   string
+}
+
+const (
+	providerGitHub = "github"
+	providerGitLab = "gitlab"
+)
+
+// newHost picks a Host implementation for the given remote URL, dispatching
+// on the remote's hostname. github.com and gitlab.com are recognized out of
+// the box; self-hosted instances are mapped via GIT_OPEN_HOSTS, e.g.
+//
+//	GIT_OPEN_HOSTS=gitlab.mycorp.com=gitlab;git.internal=github
+func newHost(remoteURL string) (Host, error) {
+	host := parseRepoURLHost(remoteURL)
+	org := parseRepoURLProjectOrg(remoteURL)
+	name := parseRepoURLProjectName(remoteURL)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return newHostFromConfig(host, org, name, cfg)
+}
+
+func newHostFromConfig(host, org, name string, cfg *Config) (Host, error) {
+	switch hostProvider(host) {
+	case providerGitHub:
+		return newGitHubHost(host, org, name, cfg), nil
+	case providerGitLab:
+		return newGitLabHost(host, org, name, cfg), nil
+	default:
+		return nil, fmt.Errorf("unrecognized provider for git host %q in GIT_OPEN_HOSTS, use %q or %q", host, providerGitHub, providerGitLab)
+	}
+}
+
+// hostProvider maps a hostname to a provider name, consulting GIT_OPEN_HOSTS
+// before falling back to the well-known public hosts. Unknown self-hosted
+// instances default to gitlab, matching git-open's original GitLab-only
+// behavior.
+func hostProvider(host string) string {
+	for _, pair := range strings.Split(os.Getenv("GIT_OPEN_HOSTS"), ";") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) == 2 && parts[0] == host {
+			return parts[1]
+		}
+	}
+
+	switch host {
+	case "github.com":
+		return providerGitHub
+	default:
+		return providerGitLab
+	}
+}
+
+func hostmust(host Host, err error) Host {
+	must(err)
+	return host
+}
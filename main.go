@@ -3,13 +3,12 @@ package main
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"strconv"
 	"strings"
-
-	gitlab "github.com/xanzy/go-gitlab"
 )
 
 func getBranch() (string, error) {
@@ -31,123 +30,130 @@ func getBranch() (string, error) {
 	return "", errors.New("unable to get current working branch")
 }
 
-func getProjectID(client *gitlab.Client) (string, error) {
-	log.Println("getting gitlab project id")
-
-	name, err := getProjectName()
-	if err != nil {
+func getHeadSHA() (string, error) {
+	out := &bytes.Buffer{}
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Stdout = out
+	if err := cmd.Run(); err != nil {
 		return "", err
 	}
+	return strings.TrimSpace(out.String()), nil
+}
 
-	org, err := getProjectOrg()
+func getProjectHomeURL(preferred string) (string, error) {
+	url, err := getProjectRemote(preferred)
 	if err != nil {
 		return "", err
 	}
+	return parseRepoURLProjectURL(url), nil
+}
 
-	opt := &gitlab.ListProjectsOptions{
-		Search: stringptr(name),
-	}
+// getProjectRemote returns the push URL of the "preferred" remote (e.g.
+// "upstream"), falling back to the first remote with a push URL if
+// preferred is empty or not found. This lets fork+upstream workflows open
+// the canonical repo instead of always following "origin".
+func getProjectRemote(preferred string) (string, error) {
+	_, url, err := getProjectRemoteInfo(preferred)
+	return url, err
+}
 
-	projs, _, err := client.Projects.ListProjects(opt)
-	if err != nil {
-		return "", err
-	} else if len(projs) == 0 {
-		return "", errors.New("got back no projects matching name")
+// getProjectRemoteInfo is like getProjectRemote but also returns the
+// matched remote's name, e.g. so callers can push back to the remote they
+// just read from rather than assuming "origin".
+func getProjectRemoteInfo(preferred string) (name, url string, err error) {
+	out := &bytes.Buffer{}
+	cmd := exec.Command("git", "remote", "-v")
+	cmd.Stdout = out
+	if err := cmd.Run(); err != nil {
+		return "", "", err
 	}
 
-	for _, proj := range projs {
-		if strings.HasSuffix(proj.SSHURLToRepo, org+"/"+name+".git") {
-			return strconv.Itoa(proj.ID), nil
+	var firstName, firstURL string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if line == "" {
+			continue
 		}
-	}
 
-	return "", errors.New("unable to find project")
-}
-
-func getMRURL(client *gitlab.Client, projID, branch string) (string, error) {
-	log.Printf("finding MRs for project id(%s) on branch %s", projID, branch)
+		parts := strings.Fields(line)
+		if len(parts) < 3 || parts[2] != "(push)" {
+			continue
+		}
 
-	opt := &gitlab.ListProjectMergeRequestsOptions{
-		SourceBranch: stringptr(branch),
-		State:        stringptr("opened"),
-		View:         stringptr("simple"),
+		if firstName == "" {
+			firstName, firstURL = parts[0], parts[1]
+		}
+		if preferred != "" && parts[0] == preferred {
+			return parts[0], parts[1], nil
+		}
 	}
 
-	mrs, _, err := client.MergeRequests.ListProjectMergeRequests(projID, opt)
-	if err != nil {
-		return "", err
-	} else if len(mrs) == 0 {
-		return "", errors.New("no matching MRs found")
+	if firstName == "" {
+		return "", "", errors.New("unable to find remote push url")
 	}
 
-	return mrs[0].WebURL, nil
+	return firstName, firstURL, nil
 }
 
-func getProjectName() (string, error) {
-	url, err := getProjectRemote()
-	if err != nil {
-		return "", err
+// extractFlags pulls git-open's global flags out of args, returning the
+// remaining positional arguments alongside the flag values. --remote (or
+// -r) picks which git remote to open, falling back to the
+// "git-open.remote" git config value; --push (or -p) offers to push the
+// current branch before creating a new pull/merge request.
+func extractFlags(args []string) (rest []string, remote string, push bool) {
+	rest = make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--remote" || args[i] == "-r":
+			if i+1 < len(args) {
+				remote = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(args[i], "--remote="):
+			remote = strings.TrimPrefix(args[i], "--remote=")
+		case args[i] == "--push" || args[i] == "-p":
+			push = true
+		default:
+			rest = append(rest, args[i])
+		}
 	}
-	return parseRepoURLProjectName(url), nil
-}
 
-func getProjectOrg() (string, error) {
-	url, err := getProjectRemote()
-	if err != nil {
-		return "", err
+	if remote == "" {
+		remote = gitConfigRemote()
 	}
-	return parseRepoURLProjectOrg(url), nil
-}
 
-func getProjectHomeURL() (string, error) {
-	url, err := getProjectRemote()
-	if err != nil {
-		return "", err
-	}
-	return parseRepoURLProjectURL(url), nil
+	return rest, remote, push
 }
 
-func getProjectRemote() (string, error) {
+func gitConfigRemote() string {
 	out := &bytes.Buffer{}
-	cmd := exec.Command("git", "remote", "-v")
+	cmd := exec.Command("git", "config", "git-open.remote")
 	cmd.Stdout = out
 	if err := cmd.Run(); err != nil {
-		return "", err
-	}
-
-	for _, line := range strings.Split(out.String(), "\n") {
-		if line == "" {
-			continue
-		}
-
-		parts := strings.Fields(line)
-
-		if len(parts) < 3 {
-			continue
-		} else if parts[2] == "(push)" {
-			return parts[1], nil
-		}
+		return ""
 	}
 
-	return "", errors.New("unable to find remote push url")
+	return strings.TrimSpace(out.String())
 }
 
-func parseRepoURLProjectName(rawurl string) string {
-	hostAndProject := strings.SplitN(rawurl, ":", 2)
-	orgAndName := strings.SplitN(hostAndProject[1], "/", 2)
-	return strings.TrimSuffix(orgAndName[1], ".git")
+// hasUpstream reports whether branch already has an upstream tracking
+// branch configured.
+func hasUpstream(branch string) bool {
+	return exec.Command("git", "rev-parse", "--abbrev-ref", branch+"@{upstream}").Run() == nil
 }
 
-func parseRepoURLProjectOrg(rawurl string) string {
-	hostAndProject := strings.SplitN(rawurl, ":", 2)
-	orgAndName := strings.SplitN(hostAndProject[1], "/", 2)
-	return orgAndName[0]
-}
+// pushBranch pushes branch to the remote getProjectRemote would pick absent
+// a --remote override (the first (push) remote, i.e. the user's own
+// "origin"-equivalent), not an unconditional "origin" literal, since
+// --remote may have pointed the rest of the command at a read-only
+// upstream the user can't push to.
+func pushBranch(branch string) error {
+	name, _, err := getProjectRemoteInfo("")
+	if err != nil {
+		return err
+	}
 
-// git@github.com:minond/git-open
-func parseRepoURLProjectURL(rawurl string) string {
-	userAndRest := strings.SplitN(rawurl, "@", 2)
-	return "https://" + strings.TrimSuffix(strings.Replace(userAndRest[1], ":", "/", 1), ".git")
+	log.Printf("pushing %s to %s (no upstream configured)", branch, name)
+	return exec.Command("git", "push", "-u", name, branch).Run()
 }
 
 func load(url string) error {
@@ -183,35 +189,147 @@ func stringmust(str string, err error) string {
 	return str
 }
 
-func getGitlabClient() *gitlab.Client {
-	client := gitlab.NewClient(nil, os.Getenv("GITLAB_API_KEY"))
-	if os.Getenv("GITLAB_HOST") != "" {
-		client.SetBaseURL(os.Getenv("GITLAB_HOST"))
-	}
-	return client
+func issuesmust(issues []Issue, err error) []Issue {
+	must(err)
+	return issues
 }
 
 func main() {
+	args, remote, push := extractFlags(os.Args[1:])
+
 	arg := "home"
-	if len(os.Args) > 1 {
-		arg = os.Args[1]
+	if len(args) > 0 {
+		arg = args[0]
 	}
 
 	switch arg {
-	case "mr":
-		client := getGitlabClient()
+	case "pr":
+		host := hostmust(newHost(stringmust(getProjectRemote(remote))))
+		branch := stringmust(getBranch())
+
+		// The branch itself always lives on the user's own remote (the
+		// first (push) remote, ignoring --remote), which may differ from
+		// the --remote-selected target (e.g. "upstream") this Host talks
+		// to in a fork+upstream workflow.
+		_, headURL, err := getProjectRemoteInfo("")
+		must(err)
+		headOrg := parseRepoURLProjectOrg(headURL)
+
+		prURL, err := host.PullRequestURL(headOrg, branch)
+		if errors.Is(err, ErrNoPullRequest) {
+			if push && !hasUpstream(branch) {
+				must(pushBranch(branch))
+			}
+			target := stringmust(host.DefaultBranch())
+			prURL = host.NewPullRequestURL(headOrg, branch, target)
+		} else {
+			must(err)
+		}
+		must(load(prURL))
+
+	case "issue":
+		host := hostmust(newHost(stringmust(getProjectRemote(remote))))
+		if len(args) > 1 {
+			id, err := strconv.Atoi(args[1])
+			must(err)
+			must(load(stringmust(host.IssueURL(id))))
+			return
+		}
+		must(load(stringmust(pickIssue(issuesmust(host.MyOpenIssues())))))
+
+	case "ci":
+		host := hostmust(newHost(stringmust(getProjectRemote(remote))))
+		sha := stringmust(getHeadSHA())
+		must(load(stringmust(host.CIStatusURL(sha))))
+
+	case "commit":
+		host := hostmust(newHost(stringmust(getProjectRemote(remote))))
+		sha := stringmust(getHeadSHA())
+		if len(args) > 1 {
+			sha = args[1]
+		}
+		must(load(host.CommitURL(sha)))
+
+	case "blob":
+		if len(args) < 2 {
+			log.Fatal("usage: git-open blob PATH[:LINE[-LINE]]")
+		}
+		host := hostmust(newHost(stringmust(getProjectRemote(remote))))
 		branch := stringmust(getBranch())
-		projID := stringmust(getProjectID(client))
-		mrURL := stringmust(getMRURL(client, projID, branch))
-		must(load(mrURL))
+		path, startLine, endLine := parseBlobArg(args[1])
+		must(load(host.BlobURL(branch, path, startLine, endLine)))
+
+	case "configure":
+		must(runConfigure(remote))
 
 	case "home":
 		fallthrough
 	case "homepage":
-		homeURL := stringmust(getProjectHomeURL())
+		homeURL := stringmust(getProjectHomeURL(remote))
 		must(load(homeURL))
 
 	default:
 		log.Fatalf("invalid argument `%s`\n", arg)
 	}
 }
+
+// runConfigure prompts for an API token for the current repo's host,
+// validates it against the API, and saves it to the git-open config file.
+func runConfigure(remote string) error {
+	url, err := getProjectRemote(remote)
+	if err != nil {
+		return err
+	}
+
+	host := parseRepoURLHost(url)
+
+	// The token env vars take precedence over config tokens; clear them so
+	// the probe below validates the token entered here.
+	for _, v := range []string{"GITLAB_API_KEY", "GITHUB_API_KEY", "GITHUB_TOKEN"} {
+		os.Unsetenv(v)
+	}
+
+	fmt.Printf("enter API token for %s: ", host)
+	var token string
+	if _, err := fmt.Scanln(&token); err != nil {
+		return err
+	}
+
+	probe, err := newHostFromConfig(host, "", "", &Config{Hosts: map[string]HostConfig{host: {Token: token}}})
+	if err != nil {
+		return err
+	}
+	if err := probe.ValidateToken(); err != nil {
+		return fmt.Errorf("could not validate token: %w", err)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	cfg.setToken(host, token)
+
+	return saveConfig(cfg)
+}
+
+// pickIssue prints the given issues and prompts the user to pick one,
+// returning its URL.
+func pickIssue(issues []Issue) (string, error) {
+	if len(issues) == 0 {
+		return "", errors.New("no open issues assigned to you")
+	}
+
+	for i, issue := range issues {
+		fmt.Printf("%d) #%d %s\n", i+1, issue.ID, issue.Title)
+	}
+
+	fmt.Print("select an issue: ")
+	var choice int
+	if _, err := fmt.Scanln(&choice); err != nil {
+		return "", err
+	} else if choice < 1 || choice > len(issues) {
+		return "", fmt.Errorf("invalid selection `%d`", choice)
+	}
+
+	return issues[choice-1].URL, nil
+}
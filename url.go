@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// parseRepoURLHost extracts the hostname from a git remote URL, in either
+// SSH ("git@github.com:group/proj.git") or HTTPS
+// ("https://gitlab.example.com/group/sub/proj.git") form.
+func parseRepoURLHost(rawurl string) string {
+	if rest, ok := stripURLScheme(rawurl); ok {
+		return strings.SplitN(rest, "/", 2)[0]
+	}
+
+	hostAndProject := strings.SplitN(rawurl, ":", 2)
+	userAndHost := strings.SplitN(hostAndProject[0], "@", 2)
+	if len(userAndHost) == 2 {
+		return userAndHost[1]
+	}
+	return userAndHost[0]
+}
+
+// parseRepoURLProjectName returns the last path segment of the remote,
+// i.e. the project's own name with any namespace (org, subgroups, ...)
+// stripped off.
+func parseRepoURLProjectName(rawurl string) string {
+	segments := strings.Split(parseRepoURLPath(rawurl), "/")
+	return segments[len(segments)-1]
+}
+
+// parseRepoURLProjectOrg returns the full namespace path a project lives
+// under, which may include any number of GitLab subgroups
+// (e.g. "group/subgroup" for git@gitlab.com:group/subgroup/proj.git).
+func parseRepoURLProjectOrg(rawurl string) string {
+	segments := strings.Split(parseRepoURLPath(rawurl), "/")
+	return strings.Join(segments[:len(segments)-1], "/")
+}
+
+// git@github.com:minond/git-open
+func parseRepoURLProjectURL(rawurl string) string {
+	return "https://" + parseRepoURLHost(rawurl) + "/" + parseRepoURLPath(rawurl)
+}
+
+// parseRepoURLPath returns the "<namespace>/.../<name>" portion of a remote
+// URL, with any trailing ".git" removed, handling both SSH and HTTPS remotes.
+func parseRepoURLPath(rawurl string) string {
+	rawurl = strings.TrimSuffix(rawurl, ".git")
+
+	if rest, ok := stripURLScheme(rawurl); ok {
+		parts := strings.SplitN(rest, "/", 2)
+		if len(parts) < 2 {
+			return ""
+		}
+		return parts[1]
+	}
+
+	hostAndProject := strings.SplitN(rawurl, ":", 2)
+	if len(hostAndProject) < 2 {
+		return ""
+	}
+	return hostAndProject[1]
+}
+
+func stripURLScheme(rawurl string) (string, bool) {
+	for _, scheme := range []string{"https://", "http://"} {
+		if strings.HasPrefix(rawurl, scheme) {
+			return strings.TrimPrefix(rawurl, scheme), true
+		}
+	}
+	return "", false
+}
+
+// escapeBlobPath percent-encodes a blob path one "/"-delimited segment at a
+// time, so that reserved characters within a file or directory name (spaces,
+// "#", "?", ...) are escaped without mangling the slashes that separate them.
+func escapeBlobPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,107 @@
+package main
+
+import "testing"
+
+func TestParseRepoURLHost(t *testing.T) {
+	cases := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"ssh github", "git@github.com:minond/git-open.git", "github.com"},
+		{"ssh no user", "github.com:minond/git-open.git", "github.com"},
+		{"https github", "https://github.com/minond/git-open.git", "github.com"},
+		{"https self-hosted gitlab", "https://gitlab.example.com/group/sub/proj.git", "gitlab.example.com"},
+		{"ssh self-hosted gitlab", "git@gitlab.example.com:group/sub/proj.git", "gitlab.example.com"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := parseRepoURLHost(c.url); got != c.want {
+				t.Errorf("parseRepoURLHost(%q) = %q, want %q", c.url, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseRepoURLProjectName(t *testing.T) {
+	cases := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"ssh", "git@github.com:minond/git-open.git", "git-open"},
+		{"https", "https://github.com/minond/git-open.git", "git-open"},
+		{"ssh subgroup", "git@gitlab.com:group/subgroup/proj.git", "proj"},
+		{"https nested subgroups", "https://gitlab.example.com/group/sub1/sub2/proj.git", "proj"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := parseRepoURLProjectName(c.url); got != c.want {
+				t.Errorf("parseRepoURLProjectName(%q) = %q, want %q", c.url, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseRepoURLProjectOrg(t *testing.T) {
+	cases := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"ssh", "git@github.com:minond/git-open.git", "minond"},
+		{"https", "https://github.com/minond/git-open.git", "minond"},
+		{"ssh subgroup", "git@gitlab.com:group/subgroup/proj.git", "group/subgroup"},
+		{"https nested subgroups", "https://gitlab.example.com/group/sub1/sub2/proj.git", "group/sub1/sub2"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := parseRepoURLProjectOrg(c.url); got != c.want {
+				t.Errorf("parseRepoURLProjectOrg(%q) = %q, want %q", c.url, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseRepoURLPath(t *testing.T) {
+	cases := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"ssh", "git@github.com:minond/git-open.git", "minond/git-open"},
+		{"https", "https://github.com/minond/git-open.git", "minond/git-open"},
+		{"no .git suffix", "https://github.com/minond/git-open", "minond/git-open"},
+		{"ssh nested subgroups", "git@gitlab.example.com:group/sub1/sub2/proj.git", "group/sub1/sub2/proj"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := parseRepoURLPath(c.url); got != c.want {
+				t.Errorf("parseRepoURLPath(%q) = %q, want %q", c.url, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseRepoURLProjectURL(t *testing.T) {
+	cases := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"ssh", "git@github.com:minond/git-open.git", "https://github.com/minond/git-open"},
+		{"https", "https://gitlab.example.com/group/sub/proj.git", "https://gitlab.example.com/group/sub/proj"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := parseRepoURLProjectURL(c.url); got != c.want {
+				t.Errorf("parseRepoURLProjectURL(%q) = %q, want %q", c.url, got, c.want)
+			}
+		})
+	}
+}